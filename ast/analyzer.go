@@ -3,6 +3,12 @@ package ast
 import (
 	"fmt"
 	"go/ast"
+	"go/importer"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/callgraph"
 
 	"memex/internal/memex/core"
 )
@@ -14,6 +20,7 @@ type TypeInfo struct {
 	IsInterface bool
 	Methods     []string
 	Embedded    []string
+	Object      types.Object
 }
 
 // Analyzer analyzes code relationships
@@ -23,6 +30,28 @@ type Analyzer struct {
 	types  map[string]*TypeInfo
 	calls  map[string][]string
 	uses   map[string][]string
+
+	// info and pkg hold the result of the go/types pass, populated by
+	// typeCheck. They are nil until Analyze has run.
+	info *types.Info
+	pkg  *types.Package
+
+	// useSSA, callGraphMode, callGraph and callGraphEdges back the
+	// SSA-backed call graph; see UseSSA in callgraph.go.
+	useSSA         bool
+	callGraphMode  CallGraphMode
+	callGraph      *callgraph.Graph
+	callGraphEdges []CallEdge
+
+	// analyzers, diagnostics and facts back the pluggable go/analysis
+	// support; see RegisterAnalyzer in lint.go.
+	analyzers   []*analysis.Analyzer
+	diagnostics []Diagnostic
+	facts       map[string][]string
+
+	// embeds holds the //go:embed directives found by analyzeEmbeds; see
+	// embed.go.
+	embeds []EmbedDirective
 }
 
 // NewAnalyzer creates a new analyzer
@@ -46,6 +75,22 @@ func (a *Analyzer) Analyze() error {
 		return fmt.Errorf("parser not set")
 	}
 
+	// Type-check first: analyzeTypes, analyzeCalls and analyzeUses all
+	// consult a.info/a.pkg when they're available.
+	if err := a.typeCheck(); err != nil {
+		return fmt.Errorf("type-checking: %w", err)
+	}
+
+	if a.useSSA {
+		if err := a.buildSSA(); err != nil {
+			return fmt.Errorf("building SSA call graph: %w", err)
+		}
+	}
+
+	if err := a.runAnalyzers(); err != nil {
+		return fmt.Errorf("running analyzers: %w", err)
+	}
+
 	// Analyze types
 	if err := a.analyzeTypes(); err != nil {
 		return fmt.Errorf("analyzing types: %w", err)
@@ -61,9 +106,63 @@ func (a *Analyzer) Analyze() error {
 		return fmt.Errorf("analyzing uses: %w", err)
 	}
 
+	// Find //go:embed directives
+	if err := a.analyzeEmbeds(); err != nil {
+		return fmt.Errorf("analyzing embeds: %w", err)
+	}
+
 	return nil
 }
 
+// typeCheck runs go/types over the parsed files and keeps the resulting
+// types.Info/types.Package on the analyzer. The later passes use it to
+// resolve identifiers, selector expressions and qualified types by object
+// identity instead of matching on raw names, which is what lets calls
+// through a selector (x.Foo()) or across packages resolve correctly.
+//
+// Type-checking a tree that doesn't fully resolve (missing imports, partial
+// packages) is expected, so errors are swallowed rather than failing the
+// whole analysis: types.Config.Check still returns a best-effort Info for
+// everything it could resolve.
+func (a *Analyzer) typeCheck() error {
+	files := a.parser.Files()
+	astFiles := make([]*ast.File, 0, len(files))
+	for _, f := range files {
+		astFiles = append(astFiles, f)
+	}
+	if len(astFiles) == 0 {
+		return nil
+	}
+
+	info := &types.Info{
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Implicits:  make(map[ast.Node]types.Object),
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(a.parser.FileSet(), "source", nil),
+		Error:    func(error) {}, // best effort: keep resolving past the first error
+	}
+
+	pkg, _ := conf.Check(a.packageName(), a.parser.FileSet(), astFiles, info)
+
+	a.info = info
+	a.pkg = pkg
+	return nil
+}
+
+// packageName returns the name of the package being analyzed, or "" if the
+// parser hasn't seen any files yet.
+func (a *Analyzer) packageName() string {
+	for _, pkg := range a.parser.GetPackages() {
+		return pkg
+	}
+	return ""
+}
+
 // analyzeTypes analyzes type declarations
 func (a *Analyzer) analyzeTypes() error {
 	for _, typeSpec := range a.parser.GetTypes() {
@@ -71,6 +170,12 @@ func (a *Analyzer) analyzeTypes() error {
 			Name: typeSpec.Name.Name,
 		}
 
+		if a.info != nil {
+			if obj, ok := a.info.Defs[typeSpec.Name]; ok {
+				info.Object = obj
+			}
+		}
+
 		switch t := typeSpec.Type.(type) {
 		case *ast.StructType:
 			info.IsStruct = true
@@ -98,17 +203,28 @@ func (a *Analyzer) analyzeTypes() error {
 	return nil
 }
 
-// analyzeCalls analyzes function calls
+// analyzeCalls analyzes function calls. It walks each function's body
+// separately (rather than inspecting whole files and looking up the
+// enclosing function per node) so the caller is always known without having
+// to reconstruct an ancestor chain.
 func (a *Analyzer) analyzeCalls() error {
-	for _, file := range a.parser.Files() {
-		ast.Inspect(file, func(n ast.Node) bool {
-			if call, ok := n.(*ast.CallExpr); ok {
-				if fun, ok := call.Fun.(*ast.Ident); ok {
-					caller := getCurrentFunction(n)
-					if caller != "" {
-						a.calls[caller] = append(a.calls[caller], fun.Name)
-					}
-				}
+	for _, fn := range a.parser.GetFunctions() {
+		if fn.Body == nil {
+			continue
+		}
+		caller := fn.Name.Name
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if callee := a.resolveCallee(call.Fun); callee != "" {
+				// GraphBuilder's function nodes are keyed by bare
+				// declaration name (see buildFunctions), not the
+				// package/receiver-qualified identity resolveCallee
+				// returns - strip it back down so the lookup in
+				// buildRelationships actually finds the node.
+				a.calls[caller] = append(a.calls[caller], bareName(callee))
 			}
 			return true
 		})
@@ -116,47 +232,134 @@ func (a *Analyzer) analyzeCalls() error {
 	return nil
 }
 
-// analyzeUses analyzes type usage
+// resolveCallee identifies the callee of a call expression. When type
+// information is available it resolves bare identifiers, method selectors
+// (x.Foo()) and generic instantiations (F[T](...)) to a stable object
+// identity via objectID; otherwise it falls back to the syntactic name.
+func (a *Analyzer) resolveCallee(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		if a.info != nil {
+			if obj, ok := a.info.Uses[f]; ok {
+				return objectID(obj)
+			}
+		}
+		return f.Name
+
+	case *ast.SelectorExpr:
+		if a.info != nil {
+			if sel, ok := a.info.Selections[f]; ok {
+				return objectID(sel.Obj())
+			}
+			if obj, ok := a.info.Uses[f.Sel]; ok {
+				return objectID(obj)
+			}
+		}
+		return getTypeString(f.X) + "." + f.Sel.Name
+
+	case *ast.IndexExpr: // generic instantiation: F[T](...)
+		return a.resolveCallee(f.X)
+
+	case *ast.IndexListExpr: // generic instantiation: F[T1, T2](...)
+		return a.resolveCallee(f.X)
+
+	case *ast.ParenExpr:
+		return a.resolveCallee(f.X)
+
+	default:
+		return ""
+	}
+}
+
+// bareName strips the package-path or receiver-type qualification that
+// objectID (via resolveCallee) adds, returning the plain declaration name:
+// "example.com/pkg.Foo" -> "Foo", "(*pkg.T).Method" -> "Method". A name with
+// no such qualification (the syntactic fallback in resolveCallee) is
+// returned unchanged.
+func bareName(id string) string {
+	if i := strings.LastIndex(id, ")."); i >= 0 {
+		return id[i+2:]
+	}
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		return id[i+1:]
+	}
+	return id
+}
+
+// objectID returns a stable, qualified identity for obj so that functions
+// and methods sharing a short name across packages or receivers don't
+// collide in a.calls/a.uses. Methods are keyed by receiver type so
+// recv.Method calls resolve to the exact method, not just a name.
+func objectID(obj types.Object) string {
+	if obj == nil {
+		return ""
+	}
+	if fn, ok := obj.(*types.Func); ok {
+		if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+			return fmt.Sprintf("(%s).%s", sig.Recv().Type().String(), fn.Name())
+		}
+	}
+	if pkg := obj.Pkg(); pkg != nil {
+		return pkg.Path() + "." + obj.Name()
+	}
+	return obj.Name()
+}
+
+// analyzeUses analyzes type usage. Like analyzeCalls, it walks each
+// function's body separately so the enclosing function is known directly
+// instead of via an ancestor-chain lookup.
 func (a *Analyzer) analyzeUses() error {
-	for _, file := range a.parser.Files() {
-		ast.Inspect(file, func(n ast.Node) bool {
-			if typeExpr, ok := n.(ast.Expr); ok {
-				if typeName := getTypeString(typeExpr); typeName != "" {
-					if info, exists := a.types[typeName]; exists {
-						context := getCurrentFunction(n)
-						if context != "" {
-							a.uses[context] = append(a.uses[context], info.Name)
-						}
-					}
-				}
+	for _, fn := range a.parser.GetFunctions() {
+		if fn.Body == nil {
+			continue
+		}
+		context := fn.Name.Name
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			expr, ok := n.(ast.Expr)
+			if !ok {
+				return true
+			}
+			typeName := a.getTypeName(expr)
+			if typeName == "" {
+				return true
+			}
+			info, exists := a.types[typeName]
+			if !exists {
+				return true
 			}
+			a.uses[context] = append(a.uses[context], info.Name)
 			return true
 		})
 	}
 	return nil
 }
 
-// getCurrentFunction returns the enclosing function name
-func getCurrentFunction(node ast.Node) string {
-	for n := node; n != nil; n = findParent(n) {
-		if fn, ok := n.(*ast.FuncDecl); ok {
-			return fn.Name.Name
+// getTypeName resolves the declared-type name of expr. It prefers
+// types.Info.TypeOf (which sees through aliases, qualified identifiers and
+// generic instantiations) and falls back to syntactic matching via
+// getTypeString when no type info is available for expr.
+func (a *Analyzer) getTypeName(expr ast.Expr) string {
+	if a.info != nil {
+		if tv, ok := a.info.Types[expr]; ok && tv.Type != nil {
+			if name := namedTypeName(tv.Type); name != "" {
+				return name
+			}
 		}
 	}
-	return ""
+	return getTypeString(expr)
 }
 
-// findParent finds the parent AST node
-func findParent(node ast.Node) ast.Node {
-	var parent ast.Node
-	ast.Inspect(node, func(n ast.Node) bool {
-		if n == node {
-			return false
-		}
-		parent = n
-		return true
-	})
-	return parent
+// namedTypeName unwraps pointers to return the declaration name of a named
+// type, or "" for unnamed types (basic types, slices, maps, ...).
+func namedTypeName(t types.Type) string {
+	switch t := t.(type) {
+	case *types.Named:
+		return t.Obj().Name()
+	case *types.Pointer:
+		return namedTypeName(t.Elem())
+	default:
+		return ""
+	}
 }
 
 // getTypeString returns a string representation of a type
@@ -0,0 +1,70 @@
+package ast
+
+import (
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestObjectID(t *testing.T) {
+	pkg := types.NewPackage("example.com/pkg", "pkg")
+
+	t.Run("nil object", func(t *testing.T) {
+		if got := objectID(nil); got != "" {
+			t.Errorf("objectID(nil) = %q, want \"\"", got)
+		}
+	})
+
+	t.Run("package-level function", func(t *testing.T) {
+		sig := types.NewSignatureType(nil, nil, nil, nil, nil, false)
+		fn := types.NewFunc(token.NoPos, pkg, "Foo", sig)
+
+		want := "example.com/pkg.Foo"
+		if got := objectID(fn); got != want {
+			t.Errorf("objectID(Foo) = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("method keyed by receiver type", func(t *testing.T) {
+		tname := types.NewTypeName(token.NoPos, pkg, "T", nil)
+		named := types.NewNamed(tname, types.NewStruct(nil, nil), nil)
+		recv := types.NewVar(token.NoPos, pkg, "", named)
+		sig := types.NewSignatureType(recv, nil, nil, nil, nil, false)
+		method := types.NewFunc(token.NoPos, pkg, "Bar", sig)
+
+		want := "(" + named.String() + ").Bar"
+		if got := objectID(method); got != want {
+			t.Errorf("objectID(Bar) = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("object without a package", func(t *testing.T) {
+		v := types.NewVar(token.NoPos, nil, "x", types.Typ[types.Int])
+		if got := objectID(v); got != "x" {
+			t.Errorf("objectID(x) = %q, want %q", got, "x")
+		}
+	})
+}
+
+// TestBareName verifies bareName undoes exactly what objectID adds, so a
+// callee resolved via resolveCallee/objectID still matches the bare
+// declaration name GraphBuilder's function nodes are keyed by.
+func TestBareName(t *testing.T) {
+	cases := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{"plain name, no qualification", "Foo", "Foo"},
+		{"package-qualified function", "example.com/pkg.Foo", "Foo"},
+		{"receiver-qualified method", "(*pkg.T).Method", "Method"},
+		{"receiver-qualified method, value receiver", "(pkg.T).Method", "Method"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := bareName(c.id); got != c.want {
+				t.Errorf("bareName(%q) = %q, want %q", c.id, got, c.want)
+			}
+		})
+	}
+}
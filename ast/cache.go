@@ -0,0 +1,117 @@
+package ast
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// cacheSchemaVersion is folded into every hash Cache computes, so a change
+// to what GraphBuilder ingests from a file (a new node type, a new edge
+// kind) invalidates every entry instead of leaving stale nodes believed
+// current.
+const cacheSchemaVersion = "ast-cache-v1"
+
+// cacheRecord is the on-disk representation of one file's last ingestion:
+// the content hash it was ingested under, and every node it produced, so a
+// later change can evict exactly those nodes before re-ingesting.
+type cacheRecord struct {
+	Hash    string   `json:"hash"`
+	NodeIDs []string `json:"nodeIDs"`
+}
+
+// Cache is a small on-disk index, modeled on cmd/go/internal/cache, that
+// lets Module.ParseFile skip re-parsing a file whose contents haven't
+// changed since the last run.
+type Cache struct {
+	path    string
+	records map[string]cacheRecord
+}
+
+// LoadCache reads the cache index at path, or returns an empty Cache if it
+// doesn't exist yet.
+func LoadCache(path string) (*Cache, error) {
+	c := &Cache{path: path, records: make(map[string]cacheRecord)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("reading cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &c.records); err != nil {
+		return nil, fmt.Errorf("parsing cache: %w", err)
+	}
+	return c, nil
+}
+
+// Save writes the cache index back to its path.
+func (c *Cache) Save() error {
+	data, err := json.MarshalIndent(c.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cache: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0o644); err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	return nil
+}
+
+// HashContent returns the content hash Cache compares files under: a
+// SHA-256 of cacheSchemaVersion and content together, so a schema bump
+// invalidates every entry at once.
+func HashContent(content []byte) string {
+	h := sha256.New()
+	h.Write([]byte(cacheSchemaVersion))
+	h.Write([]byte{':'})
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Unchanged reports whether filename was last ingested under hash.
+func (c *Cache) Unchanged(filename, hash string) bool {
+	rec, ok := c.records[filename]
+	return ok && rec.Hash != "" && rec.Hash == hash
+}
+
+// StaleIDs returns the node IDs filename's last ingestion produced, without
+// removing its record - the caller deletes them one by one and reports back
+// via Forget (once every delete succeeds) or RetainStale (if some delete
+// failed), so a node is never forgotten before it's actually gone from the
+// repository.
+func (c *Cache) StaleIDs(filename string) ([]string, bool) {
+	rec, ok := c.records[filename]
+	if !ok {
+		return nil, false
+	}
+	return rec.NodeIDs, true
+}
+
+// Forget removes filename's record entirely, once every ID StaleIDs
+// returned for it has been deleted from the repository.
+func (c *Cache) Forget(filename string) {
+	delete(c.records, filename)
+}
+
+// RetainStale keeps filename's record but narrows its NodeIDs down to
+// remaining - the IDs a failed eviction didn't manage to delete - so the
+// next run retries only those instead of leaking track of them or
+// re-deleting ones that already succeeded. The hash is cleared too: leaving
+// it in place would let Unchanged report the file as unchanged again if its
+// content ever reverted to that exact hash, and the remaining stale IDs
+// would never get retried.
+func (c *Cache) RetainStale(filename string, remaining []string) {
+	rec := c.records[filename]
+	rec.Hash = ""
+	rec.NodeIDs = remaining
+	c.records[filename] = rec
+}
+
+// Update records filename's latest hash and the node IDs it produced.
+func (c *Cache) Update(filename, hash string, nodeIDs []string) {
+	c.records[filename] = cacheRecord{Hash: hash, NodeIDs: nodeIDs}
+}
@@ -0,0 +1,85 @@
+package ast
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHashContentDiffersOnContent(t *testing.T) {
+	a := HashContent([]byte("package foo"))
+	b := HashContent([]byte("package bar"))
+	if a == b {
+		t.Error("HashContent produced the same hash for different content")
+	}
+	if a != HashContent([]byte("package foo")) {
+		t.Error("HashContent is not deterministic for the same content")
+	}
+}
+
+func TestCacheUnchanged(t *testing.T) {
+	c, err := LoadCache(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+
+	if c.Unchanged("foo.go", "deadbeef") {
+		t.Error("Unchanged() = true for a file never recorded")
+	}
+
+	c.Update("foo.go", "deadbeef", []string{"n1", "n2"})
+	if !c.Unchanged("foo.go", "deadbeef") {
+		t.Error("Unchanged() = false for a file recorded under the same hash")
+	}
+	if c.Unchanged("foo.go", "newhash") {
+		t.Error("Unchanged() = true for a file recorded under a different hash")
+	}
+}
+
+func TestCacheStaleIDsAndForget(t *testing.T) {
+	c, err := LoadCache(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	c.Update("foo.go", "deadbeef", []string{"n1", "n2"})
+
+	ids, ok := c.StaleIDs("foo.go")
+	if !ok || len(ids) != 2 {
+		t.Fatalf("StaleIDs() = %v, %v, want [n1 n2], true", ids, ok)
+	}
+
+	// StaleIDs must not mutate the record: a second call sees the same IDs.
+	if ids, ok := c.StaleIDs("foo.go"); !ok || len(ids) != 2 {
+		t.Fatalf("second StaleIDs() = %v, %v, want [n1 n2], true", ids, ok)
+	}
+
+	c.Forget("foo.go")
+	if _, ok := c.StaleIDs("foo.go"); ok {
+		t.Error("StaleIDs() after Forget still returned a record")
+	}
+}
+
+func TestCacheRetainStaleKeepsRemainderForRetry(t *testing.T) {
+	c, err := LoadCache(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	c.Update("foo.go", "deadbeef", []string{"n1", "n2", "n3"})
+
+	// Simulate deleting n1 successfully, then n2 failing: only the
+	// undeleted remainder should survive for the next run to retry.
+	c.RetainStale("foo.go", []string{"n2", "n3"})
+
+	ids, ok := c.StaleIDs("foo.go")
+	if !ok || len(ids) != 2 || ids[0] != "n2" || ids[1] != "n3" {
+		t.Fatalf("StaleIDs() after RetainStale = %v, %v, want [n2 n3], true", ids, ok)
+	}
+
+	// The hash is cleared, so the file reads as changed even if its content
+	// later reverts to the exact hash it had before.
+	if c.Unchanged("foo.go", "deadbeef") {
+		t.Error("Unchanged() = true after RetainStale, want the file to still read as changed")
+	}
+	if c.Unchanged("foo.go", "") {
+		t.Error("Unchanged() = true for an empty hash after RetainStale, want the cleared hash to never match")
+	}
+}
@@ -0,0 +1,159 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/ssa"
+)
+
+// CallGraphMode selects the algorithm used to build the SSA-backed call
+// graph.
+type CallGraphMode string
+
+const (
+	CallGraphCHA CallGraphMode = "cha" // golang.org/x/tools/go/callgraph/cha
+	CallGraphRTA CallGraphMode = "rta" // golang.org/x/tools/go/callgraph/rta
+)
+
+// CallEdge is a single resolved edge from the SSA call graph, ready to be
+// fed into GraphBuilder as an ast.calls link. Caller/Callee are SSA's
+// qualified names (e.g. "(*pkg.T).Method"); CallerName/CalleeName are the
+// bare function names GraphBuilder's function nodes are keyed by.
+type CallEdge struct {
+	Caller     string
+	Callee     string
+	CallerName string
+	CalleeName string
+	Resolution string // "cha" or "rta"
+	Dynamic    bool
+	Site       token.Position
+}
+
+// UseSSA enables the SSA-backed call graph. When enabled, Analyze builds an
+// SSA program from the type-checked package and runs the selected
+// algorithm (CHA by default) instead of relying solely on the syntactic
+// analyzeCalls pass, so method calls, function values and interface
+// dispatch are resolved instead of missed.
+func (a *Analyzer) UseSSA(enabled bool) {
+	a.useSSA = enabled
+	if enabled && a.callGraphMode == "" {
+		a.callGraphMode = CallGraphCHA
+	}
+}
+
+// SetCallGraphMode selects which algorithm builds the SSA call graph.
+// Calling it implies UseSSA(true).
+func (a *Analyzer) SetCallGraphMode(mode CallGraphMode) {
+	a.useSSA = true
+	a.callGraphMode = mode
+}
+
+// CallGraph returns the SSA-backed call graph built during Analyze, or nil
+// if UseSSA was never enabled.
+func (a *Analyzer) CallGraph() *callgraph.Graph {
+	return a.callGraph
+}
+
+// CallGraphEdges returns the flattened edges of CallGraph, or nil if
+// UseSSA was never enabled.
+func (a *Analyzer) CallGraphEdges() []CallEdge {
+	return a.callGraphEdges
+}
+
+// buildSSA builds an SSA program from the type-checked package and runs the
+// selected call-graph algorithm (CHA or RTA) over it. It requires typeCheck
+// to have already populated a.pkg/a.info.
+func (a *Analyzer) buildSSA() error {
+	if a.pkg == nil || a.info == nil {
+		return fmt.Errorf("type-checking must run before building SSA")
+	}
+
+	files := a.parser.Files()
+	astFiles := make([]*ast.File, 0, len(files))
+	for _, f := range files {
+		astFiles = append(astFiles, f)
+	}
+
+	prog := ssa.NewProgram(a.parser.FileSet(), ssa.SanityCheckFunctions)
+	pkg := prog.CreatePackage(a.pkg, astFiles, a.info, false)
+	prog.Build()
+
+	var graph *callgraph.Graph
+	switch a.callGraphMode {
+	case CallGraphRTA:
+		graph = rta.Analyze(rtaRoots(pkg), true).CallGraph
+	default:
+		graph = cha.CallGraph(prog)
+	}
+
+	a.callGraph = graph
+	a.callGraphEdges = flattenCallGraph(graph, a.callGraphMode)
+	return nil
+}
+
+// rtaRoots picks the SSA functions RTA should treat as program entry
+// points. A user-written package-level main (typical of a command) is used
+// when present, alongside init if the package has one - but this tool
+// mainly ingests arbitrary library packages that have neither, so the
+// presence test must be main specifically, not init: the SSA builder always
+// synthesizes a package init even when the source declares none, so
+// checking for init here would make every package look like a command. For
+// a library, every exported top-level function is treated as a root
+// instead, since any of them could be the entry point a caller into the
+// library uses.
+func rtaRoots(pkg *ssa.Package) []*ssa.Function {
+	main := pkg.Func("main")
+	if main == nil {
+		var roots []*ssa.Function
+		for _, member := range pkg.Members {
+			fn, ok := member.(*ssa.Function)
+			if !ok || !ast.IsExported(fn.Name()) {
+				continue
+			}
+			roots = append(roots, fn)
+		}
+		return roots
+	}
+
+	roots := []*ssa.Function{main}
+	if init := pkg.Func("init"); init != nil {
+		roots = append(roots, init)
+	}
+	return roots
+}
+
+// flattenCallGraph walks graph and produces one CallEdge per resolved edge,
+// skipping edges whose caller or callee node has no SSA function (the
+// graph's synthetic root node).
+func flattenCallGraph(graph *callgraph.Graph, mode CallGraphMode) []CallEdge {
+	var edges []CallEdge
+	callgraph.GraphVisitEdges(graph, func(e *callgraph.Edge) error {
+		caller, callee := e.Caller.Func, e.Callee.Func
+		if caller == nil || callee == nil {
+			return nil
+		}
+
+		dynamic := e.Site != nil && e.Site.Common().StaticCallee() == nil
+		var site token.Position
+		if e.Site != nil {
+			site = caller.Prog.Fset.Position(e.Site.Pos())
+		}
+
+		edges = append(edges, CallEdge{
+			Caller:     caller.String(),
+			Callee:     callee.String(),
+			CallerName: caller.Name(),
+			CalleeName: callee.Name(),
+			Resolution: string(mode),
+			Dynamic:    dynamic,
+			Site:       site,
+		})
+		return nil
+	})
+	return edges
+}
@@ -0,0 +1,94 @@
+package ast
+
+import (
+	"go/ast"
+	goimporter "go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// buildTestSSAPackage type-checks and SSA-builds src (a single-file package)
+// so rtaRoots can be exercised against a real *ssa.Package, the same way
+// buildSSA produces one.
+func buildTestSSAPackage(t *testing.T, src string) *ssa.Package {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", src, 0)
+	if err != nil {
+		t.Fatalf("parsing source: %v", err)
+	}
+
+	info := &types.Info{
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Implicits:  make(map[ast.Node]types.Object),
+	}
+	conf := types.Config{Importer: goimporter.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("test", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatalf("type-checking: %v", err)
+	}
+
+	prog := ssa.NewProgram(fset, ssa.SanityCheckFunctions)
+	ssaPkg := prog.CreatePackage(pkg, []*ast.File{f}, info, false)
+	prog.Build()
+	return ssaPkg
+}
+
+func ssaFuncNames(fns []*ssa.Function) []string {
+	names := make([]string, len(fns))
+	for i, fn := range fns {
+		names[i] = fn.Name()
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRTARootsPrefersMainAndInit(t *testing.T) {
+	pkg := buildTestSSAPackage(t, `package test
+
+func main() {}
+func init() {}
+func Helper() {}
+`)
+
+	got := ssaFuncNames(rtaRoots(pkg))
+	want := []string{"init", "main"}
+	if !equalStringSlices(got, want) {
+		t.Errorf("rtaRoots() = %v, want %v", got, want)
+	}
+}
+
+func TestRTARootsFallsBackToExportedFuncs(t *testing.T) {
+	pkg := buildTestSSAPackage(t, `package test
+
+func Exported() {}
+func unexported() {}
+`)
+
+	got := ssaFuncNames(rtaRoots(pkg))
+	want := []string{"Exported"}
+	if !equalStringSlices(got, want) {
+		t.Errorf("rtaRoots() = %v, want %v", got, want)
+	}
+}
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
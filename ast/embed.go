@@ -0,0 +1,190 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EmbedDirective records a //go:embed directive found on a var declaration:
+// the variable it's attached to, the patterns it names, and the position of
+// the declaration so resolveEmbedPatterns can resolve those patterns
+// relative to the declaring file's directory.
+type EmbedDirective struct {
+	VarName  string
+	Patterns []string
+	Pos      token.Pos
+}
+
+// analyzeEmbeds scans every parsed file for //go:embed directives. Per the
+// spec, a directive is a comment immediately preceding (no blank line
+// between) a single-spec var declaration of type string, []byte or
+// embed.FS; anything else is left alone.
+func (a *Analyzer) analyzeEmbeds() error {
+	for _, file := range a.parser.Files() {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			patterns := embedPatterns(gen.Doc)
+			if patterns == nil {
+				continue
+			}
+			if len(gen.Specs) != 1 {
+				continue
+			}
+			spec, ok := gen.Specs[0].(*ast.ValueSpec)
+			if !ok || len(spec.Names) != 1 || !isEmbeddableType(spec.Type) {
+				continue
+			}
+
+			a.embeds = append(a.embeds, EmbedDirective{
+				VarName:  spec.Names[0].Name,
+				Patterns: patterns,
+				Pos:      spec.Pos(),
+			})
+		}
+	}
+	return nil
+}
+
+// Embeds returns the go:embed directives recorded by the last Analyze run.
+func (a *Analyzer) Embeds() []EmbedDirective {
+	return a.embeds
+}
+
+// embedPatterns extracts the pattern list from a "//go:embed ..." line in
+// doc, or nil if doc carries no such directive.
+func embedPatterns(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	for _, c := range doc.List {
+		rest, ok := strings.CutPrefix(c.Text, "//go:embed")
+		if !ok {
+			continue
+		}
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields
+		}
+	}
+	return nil
+}
+
+// isEmbeddableType reports whether t is one of the three types go:embed
+// accepts: string, []byte, or embed.FS.
+func isEmbeddableType(t ast.Expr) bool {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name == "string"
+	case *ast.ArrayType:
+		elt, ok := t.Elt.(*ast.Ident)
+		return t.Len == nil && ok && elt.Name == "byte"
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		return ok && pkg.Name == "embed" && t.Sel.Name == "FS"
+	default:
+		return false
+	}
+}
+
+// resolveEmbedPatterns expands patterns (exactly as they appear after
+// //go:embed) relative to dir into the sorted set of files they select,
+// slash-separated and relative to dir. It enforces the rules a standalone
+// tool can check without a full module graph: a pattern may not be the bare
+// wildcard "*"; an "all:" prefix includes files and directories that would otherwise
+// be skipped for starting with "." or "_"; and a directory expands to every
+// matching file beneath it, recursively, while matches may not escape dir.
+func resolveEmbedPatterns(dir string, patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+
+	for _, raw := range patterns {
+		all := strings.HasPrefix(raw, "all:")
+		pattern := strings.TrimPrefix(raw, "all:")
+
+		if pattern == "*" {
+			return nil, fmt.Errorf("pattern %q is invalid: cannot use \"*\" as the first character", raw)
+		}
+
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", raw, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("pattern %q matched no files", raw)
+		}
+
+		for _, match := range matches {
+			if err := addEmbedMatch(dir, match, all, seen, &files); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// addEmbedMatch adds match (a file or directory beneath dir) to files,
+// expanding directories recursively and skipping dot/underscore-prefixed
+// entries unless all is set.
+func addEmbedMatch(dir, match string, all bool, seen map[string]bool, files *[]string) error {
+	info, err := os.Stat(match)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", match, err)
+	}
+
+	if !info.IsDir() {
+		if !all && isHiddenEmbedEntry(filepath.Base(match)) {
+			return nil
+		}
+		return recordEmbedMatch(dir, match, seen, files)
+	}
+
+	return filepath.Walk(match, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != match && !all && isHiddenEmbedEntry(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !all && isHiddenEmbedEntry(info.Name()) {
+			return nil
+		}
+		return recordEmbedMatch(dir, path, seen, files)
+	})
+}
+
+// recordEmbedMatch appends path's slash-separated location relative to dir
+// to files, deduplicating via seen and rejecting paths that resolve outside
+// dir.
+func recordEmbedMatch(dir, path string, seen map[string]bool, files *[]string) error {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("pattern resolves to %s, outside package directory %s", path, dir)
+	}
+	rel = filepath.ToSlash(rel)
+	if !seen[rel] {
+		seen[rel] = true
+		*files = append(*files, rel)
+	}
+	return nil
+}
+
+// isHiddenEmbedEntry reports whether name would be skipped by a directory or
+// wildcard match unless the pattern carried an "all:" prefix.
+func isHiddenEmbedEntry(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
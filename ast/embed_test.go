@@ -0,0 +1,89 @@
+package ast
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveEmbedPatternsMatchesGlob(t *testing.T) {
+	dir := t.TempDir()
+	writeEmbedFile(t, dir, "a.txt", "a")
+	writeEmbedFile(t, dir, "b.txt", "b")
+
+	got, err := resolveEmbedPatterns(dir, []string{"*.txt"})
+	if err != nil {
+		t.Fatalf("resolveEmbedPatterns() error = %v", err)
+	}
+	want := []string{"a.txt", "b.txt"}
+	if !equalEmbedFiles(got, want) {
+		t.Errorf("resolveEmbedPatterns(*.txt) = %v, want %v", got, want)
+	}
+}
+
+func TestResolveEmbedPatternsRejectsLeadingStar(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := resolveEmbedPatterns(dir, []string{"*"}); err == nil {
+		t.Fatal("resolveEmbedPatterns(\"*\"): want error, got nil")
+	}
+}
+
+func TestResolveEmbedPatternsSkipsHiddenUnlessAll(t *testing.T) {
+	dir := t.TempDir()
+	writeEmbedFile(t, dir, "visible.txt", "v")
+	writeEmbedFile(t, dir, filepath.Join(".hidden", "secret.txt"), "s")
+
+	got, err := resolveEmbedPatterns(dir, []string{"."})
+	if err != nil {
+		t.Fatalf("resolveEmbedPatterns(\".\") error = %v", err)
+	}
+	want := []string{"visible.txt"}
+	if !equalEmbedFiles(got, want) {
+		t.Errorf("resolveEmbedPatterns(\".\") = %v, want %v", got, want)
+	}
+
+	got, err = resolveEmbedPatterns(dir, []string{"all:."})
+	if err != nil {
+		t.Fatalf("resolveEmbedPatterns(\"all:.\") error = %v", err)
+	}
+	want = []string{".hidden/secret.txt", "visible.txt"}
+	if !equalEmbedFiles(got, want) {
+		t.Errorf("resolveEmbedPatterns(\"all:.\") = %v, want %v", got, want)
+	}
+}
+
+func TestResolveEmbedPatternsRejectsEscapingDir(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "pkg")
+	writeEmbedFile(t, parent, "outside.txt", "o")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if _, err := resolveEmbedPatterns(dir, []string{"../outside.txt"}); err == nil {
+		t.Fatal("resolveEmbedPatterns(\"../outside.txt\"): want error, got nil")
+	}
+}
+
+func writeEmbedFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+}
+
+func equalEmbedFiles(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
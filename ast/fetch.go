@@ -0,0 +1,126 @@
+package ast
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/vcs"
+)
+
+// resolveImportPath resolves pattern - a bare Go import path, optionally
+// carrying an "@version" suffix and/or a trailing "/..." wildcard - to a
+// local directory pattern Parser.ParsePath can load. It checks GOROOT/src
+// and every GOPATH workspace first, so stdlib packages and anything already
+// on disk resolve without touching the network, then falls back to
+// discovering the path's VCS root with golang.org/x/tools/go/vcs and
+// cloning or updating it into a cache directory under XDG_CACHE_HOME (or
+// ~/.cache/memex-ast).
+func resolveImportPath(pattern string) (string, error) {
+	importPath, suffix := splitPatternSuffix(pattern)
+	importPath, version, _ := strings.Cut(importPath, "@")
+
+	if dir, ok := localImportDir(importPath); ok {
+		return withSuffix(dir, suffix), nil
+	}
+
+	dir, err := fetchImportPath(importPath, version)
+	if err != nil {
+		return "", err
+	}
+	return withSuffix(dir, suffix), nil
+}
+
+// splitPatternSuffix separates a trailing "/..." (a go/packages recursive
+// wildcard) from the import path in front of it.
+func splitPatternSuffix(pattern string) (importPath, suffix string) {
+	if rest, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return rest, "/..."
+	}
+	return pattern, ""
+}
+
+// withSuffix re-attaches suffix (as produced by splitPatternSuffix) to dir.
+func withSuffix(dir, suffix string) string {
+	if suffix == "" {
+		return dir
+	}
+	return filepath.Join(dir, suffix)
+}
+
+// localImportDir checks GOROOT/src and every workspace in GOPATH for
+// importPath, returning the first match.
+func localImportDir(importPath string) (string, bool) {
+	roots := []string{filepath.Join(build.Default.GOROOT, "src")}
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		if gopath != "" {
+			roots = append(roots, filepath.Join(gopath, "src"))
+		}
+	}
+
+	for _, root := range roots {
+		dir := filepath.Join(root, filepath.FromSlash(importPath))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// fetchImportPath discovers importPath's VCS root, clones or updates it
+// into the module cache directory, checks out version if one was given, and
+// returns the directory within that checkout corresponding to importPath.
+func fetchImportPath(importPath, version string) (string, error) {
+	root, err := vcs.RepoRootForImportPath(importPath, false)
+	if err != nil {
+		return "", fmt.Errorf("resolving VCS root for %s: %w", importPath, err)
+	}
+
+	cacheDir, err := moduleCacheDir()
+	if err != nil {
+		return "", err
+	}
+	repoDir := filepath.Join(cacheDir, filepath.FromSlash(root.Root))
+
+	switch _, err := os.Stat(repoDir); {
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(filepath.Dir(repoDir), 0o755); err != nil {
+			return "", fmt.Errorf("creating cache dir: %w", err)
+		}
+		if err := root.VCS.Create(repoDir, root.Repo); err != nil {
+			return "", fmt.Errorf("cloning %s: %w", root.Repo, err)
+		}
+	case err == nil:
+		if err := root.VCS.Download(repoDir); err != nil {
+			return "", fmt.Errorf("updating %s: %w", repoDir, err)
+		}
+	default:
+		return "", fmt.Errorf("checking cache dir: %w", err)
+	}
+
+	if version != "" {
+		if err := root.VCS.TagSync(repoDir, version); err != nil {
+			return "", fmt.Errorf("checking out %s@%s: %w", importPath, version, err)
+		}
+	}
+
+	sub := strings.TrimPrefix(strings.TrimPrefix(importPath, root.Root), "/")
+	return filepath.Join(repoDir, filepath.FromSlash(sub)), nil
+}
+
+// moduleCacheDir returns the directory VCS checkouts are cached under:
+// $XDG_CACHE_HOME/memex-ast, or ~/.cache/memex-ast if XDG_CACHE_HOME isn't
+// set.
+func moduleCacheDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolving home directory: %w", err)
+		}
+		base = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(base, "memex-ast"), nil
+}
@@ -1,7 +1,16 @@
 package ast
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/systemshift/memex/pkg/module"
 )
@@ -14,15 +23,21 @@ type GraphBuilder struct {
 	packages  map[string]string
 	types     map[string]string
 	functions map[string]string
+
+	// nodesByFile tracks which nodes were produced from a declaration in
+	// each source file, so Module.ParseFile's incremental cache can evict
+	// exactly those nodes when the file changes; see recordNodeFile.
+	nodesByFile map[string][]string
 }
 
 // NewGraphBuilder creates a new graph builder
 func NewGraphBuilder(repo module.Repository) *GraphBuilder {
 	return &GraphBuilder{
-		repo:      repo,
-		packages:  make(map[string]string),
-		types:     make(map[string]string),
-		functions: make(map[string]string),
+		repo:        repo,
+		packages:    make(map[string]string),
+		types:       make(map[string]string),
+		functions:   make(map[string]string),
+		nodesByFile: make(map[string][]string),
 	}
 }
 
@@ -57,27 +72,313 @@ func (g *GraphBuilder) Build() error {
 		return fmt.Errorf("building relationships: %w", err)
 	}
 
+	// Build SSA-backed call links (only populated when the analyzer has
+	// UseSSA enabled)
+	if err := g.buildSSACalls(); err != nil {
+		return fmt.Errorf("building SSA calls: %w", err)
+	}
+
+	// Build package import edges
+	if err := g.buildImports(); err != nil {
+		return fmt.Errorf("building imports: %w", err)
+	}
+
+	// Build lint diagnostic nodes from any registered go/analysis analyzers
+	if err := g.buildDiagnostics(); err != nil {
+		return fmt.Errorf("building diagnostics: %w", err)
+	}
+
+	// Build nodes/links for //go:embed directives
+	if err := g.buildEmbeds(); err != nil {
+		return fmt.Errorf("building embeds: %w", err)
+	}
+
 	return nil
 }
 
-// buildPackages creates package nodes
+// buildEmbeds turns each //go:embed directive found by analyzeEmbeds into an
+// ast.var node for the declaring identifier, one ast.asset node (a
+// content-hashed blob) per file the directive's patterns matched, and an
+// ast.embeds link from the variable to each asset, carrying the directive's
+// patterns on the link metadata.
+func (g *GraphBuilder) buildEmbeds() error {
+	for _, embed := range g.analyzer.Embeds() {
+		filename := g.analyzer.parser.FileSet().Position(embed.Pos).Filename
+		dir := filepath.Dir(filename)
+
+		files, err := resolveEmbedPatterns(dir, embed.Patterns)
+		if err != nil {
+			return fmt.Errorf("resolving embed patterns for %s: %w", embed.VarName, err)
+		}
+
+		varMeta := map[string]interface{}{
+			"module":   "ast",
+			"name":     embed.VarName,
+			"patterns": embed.Patterns,
+		}
+		g.annotateBuildContexts(varMeta, []token.Pos{embed.Pos})
+		varID, err := g.repo.AddNode([]byte(embed.VarName), NodeTypeVar, varMeta)
+		if err != nil {
+			return fmt.Errorf("adding embed var node: %w", err)
+		}
+		g.recordNodeFile(embed.Pos, varID)
+
+		for _, rel := range files {
+			content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+			if err != nil {
+				return fmt.Errorf("reading embedded asset %s: %w", rel, err)
+			}
+			sum := sha256.Sum256(content)
+
+			assetMeta := map[string]interface{}{
+				"module": "ast",
+				"path":   rel,
+				"hash":   hex.EncodeToString(sum[:]),
+			}
+			assetID, err := g.repo.AddNode(content, NodeTypeAsset, assetMeta)
+			if err != nil {
+				return fmt.Errorf("adding embed asset node: %w", err)
+			}
+			g.recordNodeFile(embed.Pos, assetID)
+
+			linkMeta := map[string]interface{}{
+				"module":  "ast",
+				"pattern": strings.Join(embed.Patterns, " "),
+			}
+			if err := g.repo.AddLink(varID, assetID, LinkTypeEmbeds, linkMeta); err != nil {
+				return fmt.Errorf("adding embeds link: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// buildDiagnostics turns the diagnostics recorded by the go/analysis passes
+// in Analyze into ast.diagnostic nodes, linked back to the enclosing
+// function with ast.reports, so "ast lint" has a persistent, queryable
+// history instead of a one-shot CLI report.
+func (g *GraphBuilder) buildDiagnostics() error {
+	for _, diag := range g.analyzer.Diagnostics() {
+		meta := map[string]interface{}{
+			"module":   "ast",
+			"analyzer": diag.Analyzer,
+			"message":  diag.Message,
+			"category": diag.Category,
+			"position": diag.Pos.String(),
+		}
+
+		id, err := g.repo.AddNode([]byte(diag.Message), NodeTypeDiagnostic, meta)
+		if err != nil {
+			return fmt.Errorf("adding diagnostic node: %w", err)
+		}
+
+		targetID, exists := g.functions[diag.Context]
+		if !exists {
+			continue
+		}
+
+		if err := g.repo.AddLink(targetID, id, LinkTypeReports, nil); err != nil {
+			return fmt.Errorf("adding reports link: %w", err)
+		}
+	}
+	return nil
+}
+
+// buildSSACalls feeds the SSA-backed call graph (CHA/RTA) into the graph as
+// additional ast.calls links, carrying resolution metadata the syntactic
+// analyzeCalls pass can't produce (static vs. dynamic dispatch, call site).
+func (g *GraphBuilder) buildSSACalls() error {
+	for _, edge := range g.analyzer.CallGraphEdges() {
+		callerID, exists := g.functions[edge.CallerName]
+		if !exists {
+			continue
+		}
+		calleeID, exists := g.functions[edge.CalleeName]
+		if !exists {
+			continue
+		}
+
+		meta := map[string]interface{}{
+			"module":     "ast",
+			"resolution": edge.Resolution,
+			"dynamic":    edge.Dynamic,
+			"site":       edge.Site.String(),
+		}
+		if err := g.repo.AddLink(callerID, calleeID, LinkTypeCalls, meta); err != nil {
+			return fmt.Errorf("adding ssa call link: %w", err)
+		}
+	}
+	return nil
+}
+
+// buildPackages creates package nodes, one per import path rather than one
+// per bare package name, so packages sharing a short name (e.g. two
+// "util" packages in different modules) don't collide.
 func (g *GraphBuilder) buildPackages() error {
-	for _, pkg := range g.analyzer.parser.GetPackages() {
+	pkgs := g.analyzer.parser.Packages()
+	if len(pkgs) == 0 {
+		// Fall back to bare package names when the parser wasn't driven
+		// through a go/packages load (e.g. analyzer fed files directly).
+		for _, pkg := range g.analyzer.parser.GetPackages() {
+			meta := map[string]interface{}{
+				"module": "ast",
+				"name":   pkg,
+				"path":   pkg,
+			}
+			id, err := g.repo.AddNode([]byte(pkg), NodeTypePackage, meta)
+			if err != nil {
+				return fmt.Errorf("adding package node: %w", err)
+			}
+			g.packages[pkg] = id
+		}
+		return nil
+	}
+
+	// Packages() is keyed per build context (see contextSuffix); group back
+	// down to one node per import path, recording the set of
+	// goos/goarch/tags combinations it was seen under so a repository can
+	// hold multiple build variants side-by-side without node collisions.
+	grouped := make(map[string]string) // path -> package name
+	dirs := make(map[string]string)    // path -> dir
+	goosSets, goarchSets, tagSets := map[string]map[string]bool{}, map[string]map[string]bool{}, map[string]map[string]bool{}
+
+	for key, pkg := range pkgs {
+		path, goos, goarch, tags := splitContextKey(key)
+		grouped[path] = pkg.Name
+		if len(pkg.GoFiles) > 0 {
+			dirs[path] = filepath.Dir(pkg.GoFiles[0])
+		}
+		addToSet(goosSets, path, goos)
+		addToSet(goarchSets, path, goarch)
+		for _, t := range tags {
+			addToSet(tagSets, path, t)
+		}
+	}
+
+	for path, name := range grouped {
 		meta := map[string]interface{}{
 			"module": "ast",
-			"name":   pkg,
+			"path":   path,
+			"name":   name,
+		}
+		if dir, ok := dirs[path]; ok {
+			meta["dir"] = dir
+		}
+		if goos := sortedKeys(goosSets[path]); len(goos) > 0 {
+			meta["goos"] = goos
+		}
+		if goarch := sortedKeys(goarchSets[path]); len(goarch) > 0 {
+			meta["goarch"] = goarch
 		}
-		id, err := g.repo.AddNode([]byte(pkg), NodeTypePackage, meta)
+		if tags := sortedKeys(tagSets[path]); len(tags) > 0 {
+			meta["tags"] = tags
+		}
+
+		id, err := g.repo.AddNode([]byte(path), NodeTypePackage, meta)
 		if err != nil {
 			return fmt.Errorf("adding package node: %w", err)
 		}
-		g.packages[pkg] = id
+		g.packages[path] = id
 	}
 	return nil
 }
 
-// buildTypes creates type nodes
+// addToSet records value under key in sets, creating the inner set if
+// needed and ignoring empty values.
+func addToSet(sets map[string]map[string]bool, key, value string) {
+	if value == "" {
+		return
+	}
+	if sets[key] == nil {
+		sets[key] = make(map[string]bool)
+	}
+	sets[key][value] = true
+}
+
+// sortedKeys returns the keys of set in sorted order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// buildImports emits ast.imports edges between package nodes, walking each
+// loaded package's Imports (which includes stdlib and third-party
+// dependencies, not just packages we parsed source for). Edges are keyed by
+// (source, target) so the same import seen under several build contexts
+// collapses into one edge annotated with the contexts it held in.
+func (g *GraphBuilder) buildImports() error {
+	type edgeID struct{ from, to string }
+
+	goosSets, goarchSets, tagSets := map[string]map[string]bool{}, map[string]map[string]bool{}, map[string]map[string]bool{}
+	edges := make(map[string]edgeID)
+
+	for key, pkg := range g.analyzer.parser.Packages() {
+		path, goos, goarch, tags := splitContextKey(key)
+		sourceID, exists := g.packages[path]
+		if !exists {
+			continue
+		}
+
+		for depPath, dep := range pkg.Imports {
+			targetID, exists := g.packages[depPath]
+			if !exists {
+				meta := map[string]interface{}{
+					"module": "ast",
+					"path":   depPath,
+					"name":   dep.Name,
+				}
+				id, err := g.repo.AddNode([]byte(depPath), NodeTypePackage, meta)
+				if err != nil {
+					return fmt.Errorf("adding dependency package node: %w", err)
+				}
+				g.packages[depPath] = id
+				targetID = id
+			}
+
+			edgeKey := sourceID + "->" + targetID
+			edges[edgeKey] = edgeID{sourceID, targetID}
+			addToSet(goosSets, edgeKey, goos)
+			addToSet(goarchSets, edgeKey, goarch)
+			for _, t := range tags {
+				addToSet(tagSets, edgeKey, t)
+			}
+		}
+	}
+
+	for edgeKey, id := range edges {
+		meta := map[string]interface{}{"module": "ast"}
+		if goos := sortedKeys(goosSets[edgeKey]); len(goos) > 0 {
+			meta["goos"] = goos
+		}
+		if goarch := sortedKeys(goarchSets[edgeKey]); len(goarch) > 0 {
+			meta["goarch"] = goarch
+		}
+		if tags := sortedKeys(tagSets[edgeKey]); len(tags) > 0 {
+			meta["tags"] = tags
+		}
+		if err := g.repo.AddLink(id.from, id.to, LinkTypeImports, meta); err != nil {
+			return fmt.Errorf("adding import link: %w", err)
+		}
+	}
+	return nil
+}
+
+// buildTypes creates type nodes, one per name even when the same type is
+// declared once per build context (e.g. a struct redefined in foo_linux.go
+// and foo_darwin.go): all declaring positions are grouped so the node is
+// tagged with the union of build contexts instead of one AddNode call per
+// context silently overwriting g.types[name] and orphaning the previous
+// node.
 func (g *GraphBuilder) buildTypes() error {
+	declsByName := make(map[string][]*ast.TypeSpec)
+	for _, ts := range g.analyzer.parser.GetTypes() {
+		declsByName[ts.Name.Name] = append(declsByName[ts.Name.Name], ts)
+	}
+
 	for name, info := range g.analyzer.types {
 		nodeType := NodeTypeStruct
 		if info.IsInterface {
@@ -90,36 +391,145 @@ func (g *GraphBuilder) buildTypes() error {
 			"methods":  info.Methods,
 			"embedded": info.Embedded,
 		}
+		if facts := g.analyzer.Facts()[name]; len(facts) > 0 {
+			meta["facts"] = facts
+		}
+		g.annotateBuildContexts(meta, typeSpecPositions(declsByName[name]))
 
 		id, err := g.repo.AddNode([]byte(name), nodeType, meta)
 		if err != nil {
 			return fmt.Errorf("adding type node: %w", err)
 		}
 		g.types[name] = id
+		for _, ts := range declsByName[name] {
+			g.recordNodeFile(ts.Pos(), id)
+		}
 	}
 	return nil
 }
 
-// buildFunctions creates function nodes
+// typeSpecPositions returns the declaring position of each TypeSpec in specs.
+func typeSpecPositions(specs []*ast.TypeSpec) []token.Pos {
+	positions := make([]token.Pos, len(specs))
+	for i, ts := range specs {
+		positions[i] = ts.Pos()
+	}
+	return positions
+}
+
+// buildFunctions creates function nodes, one per name even when the same
+// function is declared once per build context (e.g. an OS-specific
+// implementation in foo_linux.go and foo_darwin.go sharing a name): all
+// declaring positions are grouped so the node is tagged with the union of
+// build contexts instead of one AddNode call per context silently
+// overwriting g.functions[name] and orphaning the previous node.
 func (g *GraphBuilder) buildFunctions() error {
+	declsByName := make(map[string][]*ast.FuncDecl)
+	var order []string
 	for _, fn := range g.analyzer.parser.GetFunctions() {
+		name := fn.Name.Name
+		if _, exists := declsByName[name]; !exists {
+			order = append(order, name)
+		}
+		declsByName[name] = append(declsByName[name], fn)
+	}
+
+	for _, name := range order {
+		decls := declsByName[name]
+		first := decls[0]
+
 		meta := map[string]interface{}{
 			"module": "ast",
-			"name":   fn.Name.Name,
+			"name":   name,
+		}
+		if first.Recv != nil {
+			meta["receiver"] = getTypeString(first.Recv.List[0].Type)
 		}
-		if fn.Recv != nil {
-			meta["receiver"] = getTypeString(fn.Recv.List[0].Type)
+		g.annotateBuildContexts(meta, funcDeclPositions(decls))
+		if facts := g.analyzer.Facts()[name]; len(facts) > 0 {
+			meta["facts"] = facts
 		}
 
-		id, err := g.repo.AddNode([]byte(fn.Name.Name), NodeTypeFunction, meta)
+		id, err := g.repo.AddNode([]byte(name), NodeTypeFunction, meta)
 		if err != nil {
 			return fmt.Errorf("adding function node: %w", err)
 		}
-		g.functions[fn.Name.Name] = id
+		g.functions[name] = id
+		for _, decl := range decls {
+			g.recordNodeFile(decl.Pos(), id)
+		}
 	}
 	return nil
 }
 
+// funcDeclPositions returns the declaring position of each FuncDecl in decls.
+func funcDeclPositions(decls []*ast.FuncDecl) []token.Pos {
+	positions := make([]token.Pos, len(decls))
+	for i, fn := range decls {
+		positions[i] = fn.Pos()
+	}
+	return positions
+}
+
+// recordNodeFile notes that id was produced from a declaration at pos, so
+// NodeIDsForFile can later return it to Module.ParseFile's incremental
+// cache. It's a no-op for nodes with no meaningful source position
+// (packages, dependency placeholders).
+func (g *GraphBuilder) recordNodeFile(pos token.Pos, id string) {
+	if pos == token.NoPos {
+		return
+	}
+	filename := g.analyzer.parser.FileSet().Position(pos).Filename
+	if filename == "" {
+		return
+	}
+	g.nodesByFile[filename] = append(g.nodesByFile[filename], id)
+}
+
+// NodeIDsForFile returns the IDs of every node Build has produced from a
+// declaration in filename so far, for Module.ParseFile's incremental cache
+// to evict on the next change.
+func (g *GraphBuilder) NodeIDsForFile(filename string) []string {
+	return g.nodesByFile[filename]
+}
+
+// annotateBuildContexts tags meta with the aggregated goos/goarch/tags of
+// every build context that produced a file containing one of positions, if
+// the parser recorded any (see Parser.SetBuildContexts). A node declared
+// once per context - e.g. a function or type redefined in foo_linux.go and
+// foo_darwin.go - is tagged with the full set across all its declaring
+// positions, not just whichever one happened to be visited first.
+func (g *GraphBuilder) annotateBuildContexts(meta map[string]interface{}, positions []token.Pos) {
+	goosSet, goarchSet, tagSet := map[string]bool{}, map[string]bool{}, map[string]bool{}
+
+	for _, pos := range positions {
+		filename := g.analyzer.parser.FileSet().Position(pos).Filename
+		for _, bctx := range g.analyzer.parser.FileContexts(filename) {
+			if bctx.GOOS != "" {
+				goosSet[bctx.GOOS] = true
+			}
+			if bctx.GOARCH != "" {
+				goarchSet[bctx.GOARCH] = true
+			}
+			for _, t := range bctx.BuildTags {
+				if t != "" {
+					tagSet[t] = true
+				}
+			}
+		}
+	}
+
+	if goos := sortedKeys(goosSet); len(goos) > 0 {
+		meta["goos"] = goos
+	}
+	if goarch := sortedKeys(goarchSet); len(goarch) > 0 {
+		meta["goarch"] = goarch
+	}
+	if tags := sortedKeys(tagSet); len(tags) > 0 {
+		meta["tags"] = tags
+	}
+}
+
 // buildRelationships creates relationships between nodes
 func (g *GraphBuilder) buildRelationships() error {
 	// Build function calls
@@ -180,5 +590,118 @@ func (g *GraphBuilder) buildRelationships() error {
 		}
 	}
 
+	// Build interface implementation edges
+	if err := g.buildImplementations(); err != nil {
+		return fmt.Errorf("building implementations: %w", err)
+	}
+
+	// Build struct/interface -> method containment edges
+	if err := g.buildContainment(); err != nil {
+		return fmt.Errorf("building containment: %w", err)
+	}
+
+	return nil
+}
+
+// buildImplementations emits ast.implements edges between concrete types and
+// the interfaces they satisfy. Satisfaction is determined with
+// types.Implements over the method sets collected by the go/types pass, so
+// it only runs for types the analyzer managed to resolve an Object for.
+func (g *GraphBuilder) buildImplementations() error {
+	interfaces := make(map[string]*types.Interface)
+	concretes := make(map[string]*types.Named)
+
+	for name, info := range g.analyzer.types {
+		named, ok := namedType(info.Object)
+		if !ok {
+			continue
+		}
+		if info.IsInterface {
+			if iface, ok := named.Underlying().(*types.Interface); ok {
+				interfaces[name] = iface
+			}
+			continue
+		}
+		concretes[name] = named
+	}
+
+	for typeName, concrete := range concretes {
+		typeID, exists := g.types[typeName]
+		if !exists {
+			continue
+		}
+
+		for ifaceName, iface := range interfaces {
+			if typeName == ifaceName {
+				continue
+			}
+			ifaceID, exists := g.types[ifaceName]
+			if !exists {
+				continue
+			}
+
+			switch {
+			case types.Implements(concrete, iface):
+				if err := g.addImplements(typeID, ifaceID, false); err != nil {
+					return err
+				}
+			case types.Implements(types.NewPointer(concrete), iface):
+				if err := g.addImplements(typeID, ifaceID, true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// addImplements records that concreteID satisfies ifaceID, noting whether
+// satisfaction required a pointer receiver.
+func (g *GraphBuilder) addImplements(concreteID, ifaceID string, pointerReceiver bool) error {
+	meta := map[string]interface{}{
+		"module":          "ast",
+		"pointerReceiver": pointerReceiver,
+	}
+	if err := g.repo.AddLink(concreteID, ifaceID, LinkTypeImplements, meta); err != nil {
+		return fmt.Errorf("adding implements link: %w", err)
+	}
+	return nil
+}
+
+// namedType extracts the *types.Named behind a type declaration's Object, if
+// the analyzer resolved one for it.
+func namedType(obj types.Object) (*types.Named, bool) {
+	tn, ok := obj.(*types.TypeName)
+	if !ok || tn == nil {
+		return nil, false
+	}
+	named, ok := tn.Type().(*types.Named)
+	return named, ok
+}
+
+// buildContainment emits ast.contains edges from struct/interface nodes to
+// the method nodes declared on them, so e.g. ShowImplementations can show
+// which methods satisfied an interface.
+func (g *GraphBuilder) buildContainment() error {
+	for _, fn := range g.analyzer.parser.GetFunctions() {
+		if fn.Recv == nil || len(fn.Recv.List) == 0 {
+			continue
+		}
+
+		recv := strings.TrimPrefix(getTypeString(fn.Recv.List[0].Type), "*")
+		typeID, exists := g.types[recv]
+		if !exists {
+			continue
+		}
+		fnID, exists := g.functions[fn.Name.Name]
+		if !exists {
+			continue
+		}
+
+		if err := g.repo.AddLink(typeID, fnID, LinkTypeContains, nil); err != nil {
+			return fmt.Errorf("adding contains link: %w", err)
+		}
+	}
 	return nil
 }
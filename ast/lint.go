@@ -0,0 +1,176 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/nilness"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+)
+
+// DefaultAnalyzers is the small, built-in set of go/analysis analyzers run
+// automatically after type-checking.
+var DefaultAnalyzers = []*analysis.Analyzer{
+	unusedresult.Analyzer,
+	printf.Analyzer,
+	nilness.Analyzer,
+}
+
+// Diagnostic is one analysis.Diagnostic recorded during a lint run, tagged
+// with the analyzer that produced it and the enclosing function name so
+// GraphBuilder can link it back to a node.
+type Diagnostic struct {
+	Analyzer string
+	Message  string
+	Category string
+	Pos      token.Position
+	Context  string // enclosing function name, if one was found
+}
+
+// RegisterAnalyzer adds a go/analysis analyzer to the set run after
+// type-checking, alongside DefaultAnalyzers. Analyze runs it the next time
+// it's called.
+func (a *Analyzer) RegisterAnalyzer(az *analysis.Analyzer) {
+	a.analyzers = append(a.analyzers, az)
+}
+
+// Diagnostics returns every diagnostic recorded by the last Analyze run.
+func (a *Analyzer) Diagnostics() []Diagnostic {
+	return a.diagnostics
+}
+
+// Facts returns the exported analysis.Facts recorded by the last Analyze
+// run, keyed by the bare function/type name they describe.
+func (a *Analyzer) Facts() map[string][]string {
+	return a.facts
+}
+
+// runAnalyzers runs DefaultAnalyzers plus any analyzers registered with
+// RegisterAnalyzer over the type-checked package, in dependency order, and
+// records their diagnostics and exported facts. It's a best-effort, single
+// package runner: it requires typeCheck to have already populated a.pkg and
+// a.info, and an analyzer that errors is skipped rather than aborting the
+// rest.
+func (a *Analyzer) runAnalyzers() error {
+	if a.pkg == nil || a.info == nil {
+		return nil
+	}
+
+	all := append(append([]*analysis.Analyzer{}, DefaultAnalyzers...), a.analyzers...)
+	ordered, err := sortAnalyzers(all)
+	if err != nil {
+		return fmt.Errorf("ordering analyzers: %w", err)
+	}
+
+	files := a.parser.Files()
+	astFiles := make([]*ast.File, 0, len(files))
+	for _, f := range files {
+		astFiles = append(astFiles, f)
+	}
+
+	results := make(map[*analysis.Analyzer]interface{})
+
+	for _, az := range ordered {
+		resultOf := make(map[*analysis.Analyzer]interface{}, len(az.Requires))
+		for _, dep := range az.Requires {
+			resultOf[dep] = results[dep]
+		}
+
+		pass := &analysis.Pass{
+			Analyzer:  az,
+			Fset:      a.parser.FileSet(),
+			Files:     astFiles,
+			Pkg:       a.pkg,
+			TypesInfo: a.info,
+			ResultOf:  resultOf,
+			Report: func(d analysis.Diagnostic) {
+				a.recordDiagnostic(az, d)
+			},
+			ImportObjectFact:  func(types.Object, analysis.Fact) bool { return false },
+			ExportObjectFact:  func(obj types.Object, fact analysis.Fact) { a.recordFact(obj, az, fact) },
+			ImportPackageFact: func(*types.Package, analysis.Fact) bool { return false },
+			ExportPackageFact: func(analysis.Fact) {},
+			AllObjectFacts:    func() []analysis.ObjectFact { return nil },
+			AllPackageFacts:   func() []analysis.PackageFact { return nil },
+		}
+
+		result, err := az.Run(pass)
+		if err != nil {
+			continue
+		}
+		results[az] = result
+	}
+
+	return nil
+}
+
+// recordDiagnostic appends a Diagnostic for d, tagging it with the
+// enclosing function so GraphBuilder can link it back to that node.
+func (a *Analyzer) recordDiagnostic(az *analysis.Analyzer, d analysis.Diagnostic) {
+	a.diagnostics = append(a.diagnostics, Diagnostic{
+		Analyzer: az.Name,
+		Message:  d.Message,
+		Category: d.Category,
+		Pos:      a.parser.FileSet().Position(d.Pos),
+		Context:  a.enclosingFunction(d.Pos),
+	})
+}
+
+// recordFact stringifies fact and files it under obj's bare name.
+func (a *Analyzer) recordFact(obj types.Object, az *analysis.Analyzer, fact analysis.Fact) {
+	if a.facts == nil {
+		a.facts = make(map[string][]string)
+	}
+	a.facts[obj.Name()] = append(a.facts[obj.Name()], fmt.Sprintf("%s: %v", az.Name, fact))
+}
+
+// enclosingFunction returns the name of the function declaration containing
+// pos, or "" if pos falls outside every parsed function (e.g. a
+// package-level var).
+func (a *Analyzer) enclosingFunction(pos token.Pos) string {
+	for _, fn := range a.parser.GetFunctions() {
+		if fn.Pos() <= pos && pos <= fn.End() {
+			return fn.Name.Name
+		}
+	}
+	return ""
+}
+
+// sortAnalyzers topologically orders analyzers by Requires so each
+// analyzer's dependencies run before it.
+func sortAnalyzers(analyzers []*analysis.Analyzer) ([]*analysis.Analyzer, error) {
+	var order []*analysis.Analyzer
+	done := make(map[*analysis.Analyzer]bool)
+	visiting := make(map[*analysis.Analyzer]bool)
+
+	var visit func(az *analysis.Analyzer) error
+	visit = func(az *analysis.Analyzer) error {
+		if done[az] {
+			return nil
+		}
+		if visiting[az] {
+			return fmt.Errorf("cycle in analyzer requirements at %s", az.Name)
+		}
+		visiting[az] = true
+		for _, dep := range az.Requires {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		visiting[az] = false
+		done[az] = true
+		order = append(order, az)
+		return nil
+	}
+
+	for _, az := range analyzers {
+		if err := visit(az); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
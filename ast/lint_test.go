@@ -0,0 +1,51 @@
+package ast
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestSortAnalyzersOrdersDependenciesFirst(t *testing.T) {
+	base := &analysis.Analyzer{Name: "base"}
+	mid := &analysis.Analyzer{Name: "mid", Requires: []*analysis.Analyzer{base}}
+	top := &analysis.Analyzer{Name: "top", Requires: []*analysis.Analyzer{mid, base}}
+
+	ordered, err := sortAnalyzers([]*analysis.Analyzer{top, mid, base})
+	if err != nil {
+		t.Fatalf("sortAnalyzers() error = %v", err)
+	}
+	if len(ordered) != 3 {
+		t.Fatalf("sortAnalyzers() returned %d analyzers, want 3", len(ordered))
+	}
+
+	pos := func(az *analysis.Analyzer) int {
+		for i, a := range ordered {
+			if a == az {
+				return i
+			}
+		}
+		return -1
+	}
+	if pos(base) > pos(mid) || pos(mid) > pos(top) {
+		t.Fatalf("sortAnalyzers() ordered %v, want base before mid before top", analyzerNames(ordered))
+	}
+}
+
+func TestSortAnalyzersDetectsCycle(t *testing.T) {
+	a := &analysis.Analyzer{Name: "a"}
+	b := &analysis.Analyzer{Name: "b", Requires: []*analysis.Analyzer{a}}
+	a.Requires = []*analysis.Analyzer{b}
+
+	if _, err := sortAnalyzers([]*analysis.Analyzer{a, b}); err == nil {
+		t.Fatal("sortAnalyzers() with a requirement cycle: want error, got nil")
+	}
+}
+
+func analyzerNames(analyzers []*analysis.Analyzer) []string {
+	names := make([]string, len(analyzers))
+	for i, az := range analyzers {
+		names[i] = az.Name
+	}
+	return names
+}
@@ -0,0 +1,58 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RepoLock is an advisory lock on the "LOCK" file inside a memex repository
+// directory. It exists so concurrent memex-ast processes targeting the same
+// repository - common in editor integrations and CI matrix jobs - serialize
+// instead of racing. LockRepo takes it shared or exclusive; main takes it
+// exclusively around ingestion. Module.HandleCommand takes no lock, neither
+// for the inspection commands (types, calls, impls, deps, callgraph, lint)
+// nor for parse. The underlying primitive is OS-specific;
+// see lockFile/unlockFile in lock_unix.go, lock_windows.go and
+// lock_plan9.go.
+type RepoLock struct {
+	file *os.File
+}
+
+// LockRepo opens (creating if necessary) the LOCK file inside repoDir and
+// acquires a shared or exclusive advisory lock on it, polling until it
+// succeeds or timeout elapses. A timeout of zero tries exactly once.
+func LockRepo(repoDir string, exclusive bool, timeout time.Duration) (*RepoLock, error) {
+	if err := os.MkdirAll(repoDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating repo dir: %w", err)
+	}
+
+	path := filepath.Join(repoDir, "LOCK")
+	deadline := time.Now().Add(timeout)
+	for {
+		// The open is retried too: on Plan 9 a held lock makes the open
+		// itself fail rather than lockFile.
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+		if err == nil {
+			if err = lockFile(f, exclusive); err == nil {
+				return &RepoLock{file: f}, nil
+			}
+			f.Close()
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("acquiring lock on %s: %w", path, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Unlock releases the lock and closes the underlying LOCK file.
+func (l *RepoLock) Unlock() error {
+	unlockErr := unlockFile(l.file)
+	closeErr := l.file.Close()
+	if unlockErr != nil {
+		return fmt.Errorf("releasing lock: %w", unlockErr)
+	}
+	return closeErr
+}
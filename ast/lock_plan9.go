@@ -0,0 +1,21 @@
+//go:build plan9
+
+package ast
+
+import "os"
+
+// lockFile implements Plan 9's exclusive-open locking convention: marking
+// the LOCK file ModeExclusive makes the OS refuse a second open while
+// another process already holds it open. Plan 9 has no separate
+// reader/writer lock primitive, so a shared request degrades to the same
+// exclusive-open behavior as an exclusive one.
+func lockFile(f *os.File, exclusive bool) error {
+	return os.Chmod(f.Name(), os.ModeExclusive|0o644)
+}
+
+// unlockFile releases the lock acquired by lockFile; closing the file
+// handle (done by RepoLock.Unlock right after) is what actually releases a
+// Plan 9 exclusive-use file.
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build !windows && !plan9
+
+package ast
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile acquires an advisory flock(2) lock on f, exclusive or shared, failing immediately (rather than blocking) if it's
+// already held elsewhere - LockRepo supplies the retry/timeout loop.
+func lockFile(f *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	return syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+}
+
+// unlockFile releases the lock acquired by lockFile.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,70 @@
+//go:build !windows && !plan9
+
+package ast
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockRepoExclusiveBlocksExclusive(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := LockRepo(dir, true, 0)
+	if err != nil {
+		t.Fatalf("first LockRepo() error = %v", err)
+	}
+	defer first.Unlock()
+
+	if _, err := LockRepo(dir, true, 0); err == nil {
+		t.Error("second exclusive LockRepo() with no timeout: want error while first is held, got nil")
+	}
+}
+
+func TestLockRepoUnlockReleasesForNextHolder(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := LockRepo(dir, true, 0)
+	if err != nil {
+		t.Fatalf("first LockRepo() error = %v", err)
+	}
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	second, err := LockRepo(dir, true, 0)
+	if err != nil {
+		t.Fatalf("LockRepo() after Unlock() error = %v", err)
+	}
+	defer second.Unlock()
+}
+
+func TestLockRepoTimesOutInsteadOfBlockingForever(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := LockRepo(dir, true, 0)
+	if err != nil {
+		t.Fatalf("first LockRepo() error = %v", err)
+	}
+	defer first.Unlock()
+
+	start := time.Now()
+	_, err = LockRepo(dir, true, 100*time.Millisecond)
+	if err == nil {
+		t.Fatal("LockRepo() with a short timeout while held: want error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("LockRepo() returned after %v, want at least the 100ms timeout", elapsed)
+	}
+}
+
+func TestLockRepoCreatesRepoDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "not-yet-created")
+
+	lock, err := LockRepo(dir, true, 0)
+	if err != nil {
+		t.Fatalf("LockRepo() on a missing dir: error = %v", err)
+	}
+	defer lock.Unlock()
+}
@@ -2,20 +2,26 @@ package ast
 
 import (
 	"fmt"
+	"go/build"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/systemshift/memex/pkg/sdk/types"
 )
 
 // Node types
 const (
-	NodeTypePackage   = "ast.package"
-	NodeTypeFunction  = "ast.function"
-	NodeTypeStruct    = "ast.struct"
-	NodeTypeInterface = "ast.interface"
-	NodeTypeField     = "ast.field"
-	NodeTypeMethod    = "ast.method"
-	NodeTypeImport    = "ast.import"
+	NodeTypePackage    = "ast.package"
+	NodeTypeFunction   = "ast.function"
+	NodeTypeStruct     = "ast.struct"
+	NodeTypeInterface  = "ast.interface"
+	NodeTypeField      = "ast.field"
+	NodeTypeMethod     = "ast.method"
+	NodeTypeImport     = "ast.import"
+	NodeTypeDiagnostic = "ast.diagnostic"
+	NodeTypeVar        = "ast.var"
+	NodeTypeAsset      = "ast.asset"
 )
 
 // Link types
@@ -26,6 +32,7 @@ const (
 	LinkTypeImports    = "ast.imports"    // Package imports
 	LinkTypeEmbeds     = "ast.embeds"     // Type embedding
 	LinkTypeUses       = "ast.uses"       // Type usage
+	LinkTypeReports    = "ast.reports"    // Diagnostic reported against a node
 )
 
 // Module implements AST analysis
@@ -34,6 +41,28 @@ type Module struct {
 	parser   *Parser
 	analyzer *Analyzer
 	builder  *GraphBuilder
+	cache    *Cache
+}
+
+// NewModule creates a new AST module and wires its parser, analyzer and
+// graph builder together, ready for Parse or ParsePackages.
+func NewModule(repo types.Repository) *Module {
+	m := &Module{repo: repo}
+
+	m.parser = NewParser(repo)
+	m.analyzer = NewAnalyzer(repo)
+	m.builder = NewGraphBuilder(repo)
+
+	m.analyzer.SetParser(m.parser)
+	m.builder.SetAnalyzer(m.analyzer)
+
+	// Build the CHA call graph by default so "ast callgraph" has data to
+	// walk as soon as a parse completes. Edges are recorded with resolution
+	// "cha"; "ast callgraph --mode=rta" only finds edges recorded by a parse
+	// whose analyzer was set to CallGraphRTA.
+	m.analyzer.UseSSA(true)
+
+	return m
 }
 
 // ID returns module identifier
@@ -56,8 +85,8 @@ func (m *Module) Commands() []types.ModuleCommand {
 	return []types.ModuleCommand{
 		{
 			Name:        "parse",
-			Description: "Parse Go source files",
-			Usage:       "ast parse <path>",
+			Description: "Parse Go source files or package patterns (./..., import paths)",
+			Usage:       "ast parse [--goos=os] [--goarch=arch] [--tags=tag,...] <path|pattern>...",
 		},
 		{
 			Name:        "types",
@@ -79,6 +108,16 @@ func (m *Module) Commands() []types.ModuleCommand {
 			Description: "Show package dependencies",
 			Usage:       "ast deps [package-path]",
 		},
+		{
+			Name:        "callgraph",
+			Description: "Walk the SSA-backed call graph from a root function",
+			Usage:       "ast callgraph [--mode=cha|rta|static] <root-func>",
+		},
+		{
+			Name:        "lint",
+			Description: "List recorded go/analysis diagnostics",
+			Usage:       "ast lint [analyzer]",
+		},
 	}
 }
 
@@ -118,7 +157,12 @@ func (m *Module) HandleCommand(cmd string, args []string) error {
 		if len(args) < 1 {
 			return fmt.Errorf("path required")
 		}
-		return m.Parse(args[0])
+		bctx, patterns := parseBuildFlags(args)
+		if len(patterns) < 1 {
+			return fmt.Errorf("path required")
+		}
+		m.SetBuildContext(bctx)
+		return m.ParsePackages(patterns...)
 
 	case "types":
 		typeName := ""
@@ -147,6 +191,31 @@ func (m *Module) HandleCommand(cmd string, args []string) error {
 		}
 		return m.ShowDependencies(pkgPath)
 
+	case "callgraph":
+		if len(args) < 1 {
+			return fmt.Errorf("root function required")
+		}
+		mode := string(CallGraphCHA)
+		var root string
+		for _, arg := range args {
+			if rest, ok := strings.CutPrefix(arg, "--mode="); ok {
+				mode = rest
+				continue
+			}
+			root = arg
+		}
+		if root == "" {
+			return fmt.Errorf("root function required")
+		}
+		return m.ShowCallGraph(mode, root)
+
+	case "lint":
+		analyzerName := ""
+		if len(args) > 0 {
+			analyzerName = args[0]
+		}
+		return m.ShowLint(analyzerName)
+
 	default:
 		return fmt.Errorf("unknown command: %s", cmd)
 	}
@@ -178,57 +247,333 @@ func (m *Module) Parse(path string) error {
 	return nil
 }
 
+// ParsePackages loads one or more patterns - file paths, directories,
+// "./..." style package patterns, or import paths like
+// "golang.org/x/tools/..." - with go/packages, analyzing them as whole
+// packages so cross-file and cross-package edges (calls, identifier uses,
+// interface implementations, imports) are captured instead of lost to
+// single-file AST walks.
+func (m *Module) ParsePackages(patterns ...string) error {
+	for _, pattern := range patterns {
+		resolved, err := resolvePattern(pattern)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", pattern, err)
+		}
+		if err := m.parser.ParsePath(resolved); err != nil {
+			return fmt.Errorf("loading %s: %w", pattern, err)
+		}
+	}
+
+	if err := m.analyzer.Analyze(); err != nil {
+		return fmt.Errorf("analyzing code: %w", err)
+	}
+
+	if err := m.builder.Build(); err != nil {
+		return fmt.Errorf("building graph: %w", err)
+	}
+
+	return nil
+}
+
+// resolvePattern turns a relative filesystem path into an absolute one (as
+// Parse already did for single files/directories), leaves a relative
+// pattern like "./..." untouched, and otherwise treats pattern as a Go
+// import path - resolving it against GOROOT/GOPATH or, failing that,
+// fetching it with resolveImportPath.
+func resolvePattern(pattern string) (string, error) {
+	if _, err := os.Stat(pattern); err == nil {
+		return filepath.Abs(pattern)
+	}
+	if looksLikeRelativePattern(pattern) {
+		return pattern, nil
+	}
+	return resolveImportPath(pattern)
+}
+
+// looksLikeRelativePattern reports whether pattern is a filesystem-relative
+// go/packages pattern ("./...", "../foo") rather than a bare import path, so
+// resolvePattern leaves it for go/packages to interpret instead of routing
+// it through resolveImportPath.
+func looksLikeRelativePattern(pattern string) bool {
+	return pattern == "." || pattern == ".." ||
+		strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../")
+}
+
+// SetCache enables incremental parsing for ParseFile: a file whose content
+// hash matches what was last ingested is skipped outright, and a file whose
+// hash has changed has its previous nodes evicted before being re-ingested.
+// Without a cache, ParseFile always parses and ingests.
+func (m *Module) SetCache(cache *Cache) {
+	m.cache = cache
+}
+
+// ParseFile parses a single Go source file, consulting the incremental
+// cache set by SetCache (if any) so unchanged files are skipped and changed
+// files have their stale nodes evicted before being re-ingested. Unlike
+// Parse/ParsePackages, which (re)load and analyze a whole package at a
+// time, ParseFile is meant for tight edit-loops and CI over large trees
+// where most files haven't changed since the last run.
+func (m *Module) ParseFile(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolving path: %w", err)
+	}
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+	hash := HashContent(content)
+
+	if m.cache != nil {
+		if m.cache.Unchanged(absPath, hash) {
+			return nil
+		}
+		if staleIDs, ok := m.cache.StaleIDs(absPath); ok {
+			for i, id := range staleIDs {
+				if err := m.repo.DeleteNode(id); err != nil {
+					// Keep the IDs we haven't deleted yet on record instead
+					// of forgetting them along with the ones that did
+					// succeed, so the next run retries exactly the
+					// remainder rather than leaking them or re-deleting
+					// already-gone nodes.
+					m.cache.RetainStale(absPath, staleIDs[i:])
+					return fmt.Errorf("evicting stale node %s: %w", id, err)
+				}
+			}
+			m.cache.Forget(absPath)
+		}
+	}
+
+	if err := m.parser.ParsePath(absPath); err != nil {
+		return fmt.Errorf("parsing file: %w", err)
+	}
+	if err := m.analyzer.Analyze(); err != nil {
+		return fmt.Errorf("analyzing code: %w", err)
+	}
+	if err := m.builder.Build(); err != nil {
+		return fmt.Errorf("building graph: %w", err)
+	}
+
+	if m.cache != nil {
+		m.cache.Update(absPath, hash, m.builder.NodeIDsForFile(absPath))
+		if err := m.cache.Save(); err != nil {
+			return fmt.Errorf("saving cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SetBuildContext configures the single GOOS/GOARCH/build-tag context that
+// ParsePackages parses the tree under. It's the CLI-facing counterpart to
+// Parser.SetBuildContexts: -goos, -goarch and -tags populate one
+// build.Context here instead of the full matrix that SetBuildContexts itself
+// supports, so a single ingestion run targets exactly the variant the caller
+// asked for.
+func (m *Module) SetBuildContext(bctx build.Context) {
+	m.parser.SetBuildContexts([]build.Context{bctx})
+}
+
+// parseBuildFlags pulls "--goos=", "--goarch=" and "--tags=" (comma
+// separated) out of args, starting from build.Default, and returns the
+// resulting context along with the remaining positional arguments.
+func parseBuildFlags(args []string) (build.Context, []string) {
+	bctx := build.Default
+	var rest []string
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--goos="):
+			bctx.GOOS = strings.TrimPrefix(arg, "--goos=")
+		case strings.HasPrefix(arg, "--goarch="):
+			bctx.GOARCH = strings.TrimPrefix(arg, "--goarch=")
+		case strings.HasPrefix(arg, "--tags="):
+			bctx.BuildTags = strings.Split(strings.TrimPrefix(arg, "--tags="), ",")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return bctx, rest
+}
+
 // ShowTypes shows type relationships
 func (m *Module) ShowTypes(typeName string) error {
-	// Query type relationships
-	var query string
-	if typeName != "" {
-		query = fmt.Sprintf(`type:ast.struct name:"%s"`, typeName)
-	} else {
-		query = "type:ast.struct"
+	node, err := m.repo.GetNode(typeName)
+	if err != nil {
+		return fmt.Errorf("querying nodes: %w", err)
+	}
+
+	if node.Type == NodeTypeStruct || node.Type == NodeTypeInterface {
+		fmt.Printf("%s: %s\n", node.Meta["name"], node.Type)
+		if methods, ok := node.Meta["methods"].([]string); ok {
+			fmt.Printf("  Methods: %v\n", methods)
+		}
+		if embedded, ok := node.Meta["embedded"].([]string); ok {
+			fmt.Printf("  Embedded: %v\n", embedded)
+		}
 	}
 
-	// TODO: Execute query
-	fmt.Printf("Types query: %s\n", query)
 	return nil
 }
 
 // ShowCalls shows function call graph
 func (m *Module) ShowCalls(funcName string) error {
-	// Query call graph
-	var query string
-	if funcName != "" {
-		query = fmt.Sprintf(`type:ast.function name:"%s" -[ast.calls*]->`, funcName)
-	} else {
-		query = "type:ast.function -[ast.calls]-> type:ast.function"
+	node, err := m.repo.GetNode(funcName)
+	if err != nil {
+		return fmt.Errorf("getting function: %w", err)
+	}
+
+	if node.Type == NodeTypeFunction {
+		fmt.Printf("%s:\n", node.Meta["name"])
+		links, err := m.repo.GetLinks(node.ID)
+		if err != nil {
+			return fmt.Errorf("getting links: %w", err)
+		}
+		for _, link := range links {
+			if link.Type == LinkTypeCalls {
+				callee, err := m.repo.GetNode(link.Target)
+				if err != nil {
+					continue
+				}
+				fmt.Printf("  calls: %s\n", callee.Meta["name"])
+			}
+		}
 	}
 
-	// TODO: Execute query
-	fmt.Printf("Calls query: %s\n", query)
 	return nil
 }
 
 // ShowImplementations shows interface implementations
 func (m *Module) ShowImplementations(interfaceName string) error {
-	// Query implementations
-	query := fmt.Sprintf(`type:ast.struct -[ast.implements]-> {type:ast.interface name:"%s"}`, interfaceName)
+	node, err := m.repo.GetNode(interfaceName)
+	if err != nil {
+		return fmt.Errorf("getting interface: %w", err)
+	}
+
+	if node.Type == NodeTypeInterface {
+		links, err := m.repo.GetLinks(node.ID)
+		if err != nil {
+			return fmt.Errorf("getting links: %w", err)
+		}
+		for _, link := range links {
+			if link.Type == LinkTypeImplements {
+				impl, err := m.repo.GetNode(link.Source)
+				if err != nil {
+					continue
+				}
+				fmt.Printf("%s implements %s\n", impl.Meta["name"], interfaceName)
+			}
+		}
+	}
 
-	// TODO: Execute query
-	fmt.Printf("Implementations query: %s\n", query)
 	return nil
 }
 
 // ShowDependencies shows package dependencies
 func (m *Module) ShowDependencies(pkgPath string) error {
-	// Query dependencies
-	var query string
-	if pkgPath != "" {
-		query = fmt.Sprintf(`{type:ast.package path:"%s"} -[ast.imports]-> type:ast.package`, pkgPath)
-	} else {
-		query = "type:ast.package -[ast.imports]-> type:ast.package"
+	node, err := m.repo.GetNode(pkgPath)
+	if err != nil {
+		return fmt.Errorf("getting package: %w", err)
+	}
+
+	if node.Type == NodeTypePackage {
+		fmt.Printf("%s:\n", node.Meta["path"])
+		links, err := m.repo.GetLinks(node.ID)
+		if err != nil {
+			return fmt.Errorf("getting links: %w", err)
+		}
+		for _, link := range links {
+			if link.Type == LinkTypeImports {
+				dep, err := m.repo.GetNode(link.Target)
+				if err != nil {
+					continue
+				}
+				fmt.Printf("  imports: %s\n", dep.Meta["path"])
+			}
+		}
 	}
 
-	// TODO: Execute query
-	fmt.Printf("Dependencies query: %s\n", query)
+	return nil
+}
+
+// ShowCallGraph walks the ast.calls edges reachable from root, restricted to
+// the edges recorded under the given resolution mode ("static", "cha" or
+// "rta" — see CallGraphMode), and prints every function transitively
+// reachable from it. "static" matches the syntactic analyzeCalls pass, which
+// leaves meta["resolution"] unset.
+func (m *Module) ShowCallGraph(mode, root string) error {
+	node, err := m.repo.GetNode(root)
+	if err != nil {
+		return fmt.Errorf("getting function: %w", err)
+	}
+	if node.Type != NodeTypeFunction {
+		return fmt.Errorf("%s is not a function", root)
+	}
+
+	visited := map[string]bool{node.ID: true}
+	queue := []string{node.ID}
+
+	fmt.Printf("%s (mode=%s):\n", root, mode)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		links, err := m.repo.GetLinks(id)
+		if err != nil {
+			return fmt.Errorf("getting links: %w", err)
+		}
+		for _, link := range links {
+			if link.Type != LinkTypeCalls {
+				continue
+			}
+			resolution, _ := link.Meta["resolution"].(string)
+			matches := resolution == mode
+			if mode == "static" {
+				matches = resolution == ""
+			}
+			if !matches {
+				continue
+			}
+
+			callee, err := m.repo.GetNode(link.Target)
+			if err != nil || visited[callee.ID] {
+				continue
+			}
+			visited[callee.ID] = true
+			fmt.Printf("  %s\n", callee.Meta["name"])
+			queue = append(queue, callee.ID)
+		}
+	}
+
+	return nil
+}
+
+// ShowLint lists diagnostics recorded against parsed functions (via
+// ast.reports edges), optionally restricted to one analyzer.
+func (m *Module) ShowLint(analyzerName string) error {
+	for _, fn := range m.parser.GetFunctions() {
+		node, err := m.repo.GetNode(fn.Name.Name)
+		if err != nil || node.Type != NodeTypeFunction {
+			continue
+		}
+
+		links, err := m.repo.GetLinks(node.ID)
+		if err != nil {
+			return fmt.Errorf("getting links: %w", err)
+		}
+		for _, link := range links {
+			if link.Type != LinkTypeReports {
+				continue
+			}
+			diag, err := m.repo.GetNode(link.Target)
+			if err != nil {
+				continue
+			}
+			if analyzerName != "" && diag.Meta["analyzer"] != analyzerName {
+				continue
+			}
+			fmt.Printf("%s: [%s] %s (%s)\n", fn.Name.Name, diag.Meta["analyzer"], diag.Meta["message"], diag.Meta["position"])
+		}
+	}
 	return nil
 }
@@ -3,9 +3,13 @@ package ast
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
+	"go/build"
 	"go/token"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
 
 	"memex/internal/memex/core"
 )
@@ -15,50 +19,189 @@ type Parser struct {
 	fset  *token.FileSet
 	repo  core.Repository
 	files map[string]*ast.File
+	pkgs  map[string]*packages.Package
+
+	// buildContexts is the GOOS/GOARCH/tags matrix ParsePath re-parses the
+	// tree under; defaults to a single entry equal to build.Default.
+	buildContexts []build.Context
+	// fileContexts records every build context a given filename was parsed
+	// under, keyed by plain filename - a file with no build constraint is
+	// parsed once per context in buildContexts, so this is a set rather
+	// than a single value.
+	fileContexts map[string][]build.Context
 }
 
 // NewParser creates a new parser
 func NewParser(repo core.Repository) *Parser {
 	return &Parser{
-		fset:  token.NewFileSet(),
-		repo:  repo,
-		files: make(map[string]*ast.File),
+		fset:         token.NewFileSet(),
+		repo:         repo,
+		files:        make(map[string]*ast.File),
+		pkgs:         make(map[string]*packages.Package),
+		fileContexts: make(map[string][]build.Context),
 	}
 }
 
-// ParsePath parses Go files in a path
+// SetBuildContexts configures the GOOS/GOARCH/tags matrix ParsePath
+// re-parses the tree under, so files gated by "//go:build linux" or a
+// "_windows.go" suffix are included under the contexts that select them
+// instead of being silently resolved against the host context alone. An
+// empty slice (the default) parses under build.Default only.
+func (p *Parser) SetBuildContexts(contexts []build.Context) {
+	p.buildContexts = contexts
+}
+
+// ParsePath loads the package(s) at path with golang.org/x/tools/go/packages
+// under every configured build context, and records them keyed by full
+// import path, so GraphBuilder can create package nodes that don't collide
+// across packages sharing a bare name and can emit real import-path edges.
+// path may be a directory (loaded as "<dir>/..."), a single file, or an
+// already-valid packages.Load pattern such as "./..." or an import path -
+// see toLoadPattern.
 func (p *Parser) ParsePath(path string) error {
-	// Check if path is a directory
-	info, err := os.Stat(path)
+	pattern, err := toLoadPattern(path)
 	if err != nil {
-		return fmt.Errorf("checking path: %w", err)
+		return err
 	}
 
-	if info.IsDir() {
-		// Parse directory
-		pkgs, err := parser.ParseDir(p.fset, path, nil, parser.ParseComments)
-		if err != nil {
-			return fmt.Errorf("parsing directory: %w", err)
+	contexts := p.buildContexts
+	if len(contexts) == 0 {
+		contexts = []build.Context{build.Default}
+	}
+
+	for _, bctx := range contexts {
+		if err := p.parseUnderContext(pattern, bctx); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
 
-		// Store all files
-		for _, pkg := range pkgs {
-			for filename, file := range pkg.Files {
-				p.files[filename] = file
+// parseUnderContext loads pattern once, with GOOS/GOARCH/build tags pinned
+// to bctx so the go/packages driver's own file matching (//go:build,
+// _GOOS_GOARCH.go suffixes) resolves against that context rather than the
+// host's.
+func (p *Parser) parseUnderContext(pattern string, bctx build.Context) error {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Fset: p.fset,
+		Env:  append(os.Environ(), "GOOS="+bctx.GOOS, "GOARCH="+bctx.GOARCH),
+	}
+	if len(bctx.BuildTags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(bctx.BuildTags, ",")}
+	}
+
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return fmt.Errorf("loading packages: %w", err)
+	}
+
+	for _, pkg := range pkgs {
+		p.pkgs[pkg.PkgPath+contextSuffix(bctx)] = pkg
+		for i, file := range pkg.Syntax {
+			filename := pkg.PkgPath
+			if i < len(pkg.CompiledGoFiles) {
+				filename = pkg.CompiledGoFiles[i]
 			}
+			// Key files by filename+context so a file with no build
+			// constraint - parsed identically under every context in the
+			// matrix - keeps one *ast.File per context instead of each
+			// later context silently overwriting the last.
+			p.files[filename+contextSuffix(bctx)] = file
+			p.fileContexts[filename] = append(p.fileContexts[filename], bctx)
 		}
-	} else {
-		// Parse single file
-		file, err := parser.ParseFile(p.fset, path, nil, parser.ParseComments)
-		if err != nil {
-			return fmt.Errorf("parsing file: %w", err)
-		}
-		p.files[path] = file
 	}
 
 	return nil
 }
 
+// contextSuffix disambiguates the same import path loaded under different
+// build contexts; it's empty for the host's default context so the common,
+// single-context case keeps using plain import paths as map keys.
+func contextSuffix(bctx build.Context) string {
+	if bctx.GOOS == build.Default.GOOS && bctx.GOARCH == build.Default.GOARCH && len(bctx.BuildTags) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("?goos=%s&goarch=%s&tags=%s", bctx.GOOS, bctx.GOARCH, strings.Join(bctx.BuildTags, ","))
+}
+
+// FileContexts returns every build context filename was parsed under.
+func (p *Parser) FileContexts(filename string) []build.Context {
+	return p.fileContexts[filename]
+}
+
+// splitContextKey reverses contextSuffix, separating a Packages() key back
+// into the import path and the context qualifiers contextSuffix encoded
+// onto it.
+func splitContextKey(key string) (path, goos, goarch string, tags []string) {
+	idx := strings.Index(key, "?")
+	if idx < 0 {
+		return key, "", "", nil
+	}
+
+	path = key[:idx]
+	for _, part := range strings.Split(key[idx+1:], "&") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "goos":
+			goos = kv[1]
+		case "goarch":
+			goarch = kv[1]
+		case "tags":
+			if kv[1] != "" {
+				tags = strings.Split(kv[1], ",")
+			}
+		}
+	}
+	return path, goos, goarch, tags
+}
+
+// toLoadPattern turns a file/directory path (what the CLI has always
+// accepted) into a packages.Load pattern. Anything that isn't an existing
+// path on disk is assumed to already be a valid pattern - "./...", an
+// import path, a std library package name, and so on.
+func toLoadPattern(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return path, nil
+		}
+		return "", fmt.Errorf("checking path: %w", err)
+	}
+
+	if info.IsDir() {
+		return filepath.Join(path, "..."), nil
+	}
+	return "file=" + path, nil
+}
+
+// GetPackagePaths returns the import paths of every package loaded by
+// ParsePath.
+func (p *Parser) GetPackagePaths() []string {
+	seen := make(map[string]bool, len(p.pkgs))
+	paths := make([]string, 0, len(p.pkgs))
+	for key := range p.pkgs {
+		path, _, _, _ := splitContextKey(key)
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Packages returns the packages loaded by ParsePath, keyed by import path
+// plus, for a non-default build context, the qualifiers contextSuffix
+// appends (see splitContextKey).
+func (p *Parser) Packages() map[string]*packages.Package {
+	return p.pkgs
+}
+
 // GetPackages returns unique package names
 func (p *Parser) GetPackages() []string {
 	packages := make(map[string]bool)
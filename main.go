@@ -3,9 +3,11 @@ package main
 import (
 	"flag"
 	"fmt"
+	"go/build"
 	"log"
 	"os"
-	"path/filepath"
+	"strings"
+	"time"
 
 	"memex-ast/ast"
 	"memex/internal/memex/repository"
@@ -14,15 +16,30 @@ import (
 func main() {
 	// Parse command line flags
 	repoPath := flag.String("repo", "", "Path to memex repository")
-	sourcePath := flag.String("source", "", "Path to Go source file or directory")
+	sourcePath := flag.String("source", "", "Path to Go source file/directory, or a package pattern (./..., import path)")
+	goos := flag.String("goos", "", "GOOS to parse under (defaults to the host GOOS)")
+	goarch := flag.String("goarch", "", "GOARCH to parse under (defaults to the host GOARCH)")
+	tags := flag.String("tags", "", "Comma-separated build tags to parse under")
+	lockTimeout := flag.Int("lock-timeout", 10, "Seconds to wait for the repository lock before failing")
 	flag.Parse()
 
 	if *repoPath == "" || *sourcePath == "" {
-		fmt.Println("Usage: memex-ast -repo <repository path> -source <source path>")
+		fmt.Println("Usage: memex-ast -repo <repository path> -source <source path|pattern>")
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
+	// Ingestion mutates the graph, so it takes the repository's lock
+	// exclusively before even opening/creating the repository - this is
+	// what serializes concurrent memex-ast processes (editor integrations,
+	// CI matrix jobs) instead of letting their writes race on the same
+	// repo, and the race is worst during repository creation itself.
+	lock, err := ast.LockRepo(*repoPath, true, time.Duration(*lockTimeout)*time.Second)
+	if err != nil {
+		log.Fatalf("Error acquiring repository lock: %v", err)
+	}
+	defer lock.Unlock()
+
 	// Open or create repository
 	repo, err := repository.Open(*repoPath)
 	if err != nil {
@@ -43,38 +60,28 @@ func main() {
 		log.Fatalf("Error registering module: %v", err)
 	}
 
-	// Process source path
-	sourceInfo, err := os.Stat(*sourcePath)
-	if err != nil {
-		log.Fatalf("Error accessing source path: %v", err)
+	// Pin the GOOS/GOARCH/build-tag context the source is parsed under, so
+	// files gated by "//go:build" or a "_GOOS_GOARCH.go" suffix resolve
+	// against the requested variant rather than the host's.
+	bctx := build.Default
+	if *goos != "" {
+		bctx.GOOS = *goos
+	}
+	if *goarch != "" {
+		bctx.GOARCH = *goarch
+	}
+	if *tags != "" {
+		bctx.BuildTags = strings.Split(*tags, ",")
 	}
+	module.SetBuildContext(bctx)
 
-	if sourceInfo.IsDir() {
-		// Process directory
-		err = filepath.Walk(*sourcePath, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() && filepath.Ext(path) == ".go" {
-				fmt.Printf("Processing %s...\n", path)
-				if err := module.ParseFile(path); err != nil {
-					fmt.Printf("Error processing %s: %v\n", path, err)
-				}
-			}
-			return nil
-		})
-		if err != nil {
-			log.Fatalf("Error walking directory: %v", err)
-		}
-	} else {
-		// Process single file
-		if filepath.Ext(*sourcePath) != ".go" {
-			log.Fatal("Source file must be a .go file")
-		}
-		fmt.Printf("Processing %s...\n", *sourcePath)
-		if err := module.ParseFile(*sourcePath); err != nil {
-			log.Fatalf("Error processing file: %v", err)
-		}
+	// Load and analyze the source: a file, a directory, a "./..." pattern,
+	// or an import path like "golang.org/x/tools/...". ParsePackages loads
+	// whole packages via go/packages, so cross-file and cross-package edges
+	// are captured regardless of which form *sourcePath takes.
+	fmt.Printf("Processing %s...\n", *sourcePath)
+	if err := module.ParsePackages(*sourcePath); err != nil {
+		log.Fatalf("Error processing %s: %v", *sourcePath, err)
 	}
 
 	fmt.Println("AST analysis complete")